@@ -1,23 +1,24 @@
 package cmd
 
 import (
-	"net"
-
-	"google.golang.org/grpc"
+	"log"
+	"os"
+	"syscall"
 
+	"github.com/tomasbasham/grpctest"
 	"github.com/tomasbasham/grpctest/examples/echo/server"
 	echopb "github.com/tomasbasham/grpctest/testdata/echo/v1"
 )
 
+// Serve starts the echo server on ":50051" and blocks until it receives
+// SIGINT or SIGTERM, at which point it drains in-flight RPCs before
+// returning.
 func Serve() error {
-	l, err := net.Listen("tcp", ":50051")
-	if err != nil {
-		return err
-	}
-	defer l.Close()
-
-	s := grpc.NewServer()
+	s := grpctest.NewServerWithTransport(grpctest.NewTCPTransport(":50051"))
 	echopb.RegisterEchoServiceServer(s, &server.EchoServer{})
+	s.Serve()
+
+	log.Printf("listening on %s", s.Addr())
 
-	return s.Serve(l)
+	return s.RunUntilSignal(os.Interrupt, syscall.SIGTERM)
 }
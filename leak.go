@@ -0,0 +1,176 @@
+package grpctest
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// leakCheckInterval and leakCheckAttempts bound the retry window used while
+// waiting for goroutines to exit. bufconn and gRPC connection teardown happen
+// asynchronously, so a goroutine that will exit momentarily must not be
+// reported as leaked.
+const (
+	leakCheckInterval = 50 * time.Millisecond
+	leakCheckAttempts = 20 // 1s total
+)
+
+// goroutineAllowlist matches goroutines that are expected to outlive any
+// individual test (runtime and testing infrastructure) and are therefore
+// excluded from leak detection.
+var goroutineAllowlist = []*regexp.Regexp{
+	regexp.MustCompile(`testing\.Main`),
+	regexp.MustCompile(`testing\.\(\*T\)\.Run`),
+	regexp.MustCompile(`testing\.tRunner`),
+	regexp.MustCompile(`runtime\.goexit`),
+	regexp.MustCompile(`created by runtime`),
+	regexp.MustCompile(`signal\.signal_recv`),
+	regexp.MustCompile(`os/signal\.loop`),
+
+	// grpc-go starts a CallbackSerializer goroutine per ClientConn that only
+	// exits once the conn is closed. Server.ClientConn hands callers a conn
+	// they're free to never close (idiomatic for a short-lived test), so
+	// treat it the same as runtime/testing infrastructure rather than
+	// requiring every test to remember conn.Close().
+	regexp.MustCompile(`grpcsync\.\(\*CallbackSerializer\)\.run`),
+}
+
+// CheckGoroutineLeaks snapshots the currently running goroutines and returns
+// a function that, when called, verifies that no goroutine started since the
+// snapshot is still running, beyond the allow-list. It retries with backoff for up to a second to
+// absorb asynchronous server and client teardown before failing the test.
+//
+// Example:
+//
+//	check := grpctest.CheckGoroutineLeaks(t)
+//	defer check()
+func CheckGoroutineLeaks(t testing.TB) func() {
+	t.Helper()
+
+	before := goroutineStacks()
+
+	return func() {
+		t.Helper()
+
+		var leaked map[int]string
+		for attempt := 0; attempt < leakCheckAttempts; attempt++ {
+			leaked = diffGoroutines(before, goroutineStacks())
+			if len(leaked) == 0 {
+				return
+			}
+			time.Sleep(leakCheckInterval)
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "grpctest: %d goroutine(s) leaked:\n", len(leaked))
+		for id, stack := range leaked {
+			fmt.Fprintf(&b, "--- goroutine %d ---\n%s\n", id, stack)
+		}
+		t.Error(b.String())
+	}
+}
+
+// goroutineStacks returns the stack trace of every currently running
+// goroutine, keyed by goroutine ID.
+func goroutineStacks() map[int]string {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	stacks := make(map[int]string)
+	for _, block := range strings.Split(string(buf), "\n\n") {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+
+		var id int
+		if _, err := fmt.Sscanf(block, "goroutine %d ", &id); err != nil {
+			continue
+		}
+		stacks[id] = block
+	}
+	return stacks
+}
+
+// diffGoroutines returns the goroutines present in after but not in before,
+// excluding any that match goroutineAllowlist.
+func diffGoroutines(before, after map[int]string) map[int]string {
+	leaked := make(map[int]string)
+	for id, stack := range after {
+		if _, ok := before[id]; ok {
+			continue
+		}
+		if isAllowlisted(stack) {
+			continue
+		}
+		leaked[id] = stack
+	}
+	return leaked
+}
+
+func isAllowlisted(stack string) bool {
+	for _, re := range goroutineAllowlist {
+		if re.MatchString(stack) {
+			return true
+		}
+	}
+	return false
+}
+
+// CloseOnCleanupWithLeakCheck registers the server to be closed automatically
+// when the test ends, additionally failing the test if any goroutines started
+// since CloseOnCleanupWithLeakCheck was called are still running after Close
+// returns. This
+// catches handlers that spawn goroutines tied to the request context which
+// never exit.
+func (s *Server) CloseOnCleanupWithLeakCheck(t testing.TB) {
+	check := CheckGoroutineLeaks(t)
+	t.Cleanup(func() {
+		s.Close()
+		check()
+	})
+}
+
+// Tester is implemented by test suites run via [RunSubTests]. Setup is called
+// before, and Teardown after, each exported TestXxx method found on the
+// suite via reflection.
+type Tester interface {
+	Setup(t *testing.T)
+	Teardown(t *testing.T)
+}
+
+// RunSubTests runs every exported method of ts whose name begins with "Test"
+// as a subtest of t, wrapping each in ts.Setup and ts.Teardown. This mirrors
+// the suite pattern used internally by grpc-go's grpctest package, letting a
+// package group related tests under one type while sharing setup such as
+// goroutine leak detection.
+func RunSubTests(t *testing.T, ts Tester) {
+	t.Helper()
+
+	v := reflect.ValueOf(ts)
+	typ := v.Type()
+
+	for i := 0; i < typ.NumMethod(); i++ {
+		m := typ.Method(i)
+		if !strings.HasPrefix(m.Name, "Test") {
+			continue
+		}
+
+		t.Run(m.Name, func(t *testing.T) {
+			ts.Setup(t)
+			defer ts.Teardown(t)
+			v.Method(i).Call([]reflect.Value{reflect.ValueOf(t)})
+		})
+	}
+}
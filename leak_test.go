@@ -0,0 +1,104 @@
+package grpctest_test
+
+import (
+	"testing"
+
+	"github.com/tomasbasham/grpctest"
+	echopb "github.com/tomasbasham/grpctest/testdata/echo/v1"
+)
+
+func TestCheckGoroutineLeaks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("passes when no goroutines leak", func(t *testing.T) {
+		t.Parallel()
+
+		check := grpctest.CheckGoroutineLeaks(t)
+		check()
+	})
+
+	t.Run("fails when a goroutine leaks", func(t *testing.T) {
+		t.Parallel()
+
+		done := make(chan struct{})
+		defer close(done)
+
+		ft := &fakeT{}
+		check := grpctest.CheckGoroutineLeaks(ft)
+		go func() { <-done }()
+		check()
+
+		if !ft.failed {
+			t.Error("expected leak check to fail, but it passed")
+		}
+	})
+}
+
+// fakeT is a minimal testing.TB that records whether it failed instead of
+// actually failing. It's used to exercise CheckGoroutineLeaks' failure path
+// in isolation: t.Run propagates a subtest's failure up to every ancestor
+// regardless of what the caller does with the returned bool, so driving
+// CheckGoroutineLeaks with a real *testing.T here would always fail this
+// whole package's test run.
+type fakeT struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Error(args ...any) {
+	f.failed = true
+}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.failed = true
+}
+
+func TestServer_CloseOnCleanupWithLeakCheck(t *testing.T) {
+	t.Parallel()
+
+	s := grpctest.NewServer()
+	s.CloseOnCleanupWithLeakCheck(t)
+
+	echopb.RegisterEchoServiceServer(s, &echoServer{})
+	s.Serve()
+
+	conn, err := s.ClientConn()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mustEcho(t, conn, "leak check cleanup")
+}
+
+type exampleSuite struct {
+	ran map[string]bool
+}
+
+func (s *exampleSuite) Setup(t *testing.T) {
+	if s.ran == nil {
+		s.ran = make(map[string]bool)
+	}
+}
+
+func (s *exampleSuite) Teardown(t *testing.T) {}
+
+func (s *exampleSuite) TestOne(t *testing.T) {
+	s.ran["one"] = true
+}
+
+func (s *exampleSuite) TestTwo(t *testing.T) {
+	s.ran["two"] = true
+}
+
+func TestRunSubTests(t *testing.T) {
+	t.Parallel()
+
+	suite := &exampleSuite{}
+	grpctest.RunSubTests(t, suite)
+
+	if !suite.ran["one"] || !suite.ran["two"] {
+		t.Errorf("expected both subtests to run, got: %#v", suite.ran)
+	}
+}
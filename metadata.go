@@ -0,0 +1,334 @@
+package grpctest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// RecordedCall captures the metadata, peer, and header/trailer information
+// observed for a single RPC.
+type RecordedCall struct {
+	// Method is the fully-qualified RPC method, e.g. "/echo.v1.EchoService/Echo".
+	Method string
+
+	// Peer is the peer information of the other end of the RPC, if available.
+	Peer *peer.Peer
+
+	// MD is the metadata the RPC was made with.
+	MD metadata.MD
+
+	// Header is the header metadata sent in response to the RPC. On the server
+	// side it is nil until the handler returns, even if the handler has
+	// already sent headers, so it is still nil for a call observed while in
+	// flight via [MetadataRecorder.WaitFor].
+	Header metadata.MD
+
+	// Trailer is the trailer metadata sent in response to the RPC. Like
+	// Header, it is only populated once the handler returns.
+	Trailer metadata.MD
+}
+
+// MetadataRecorder captures incoming metadata, peer information, and outgoing
+// headers/trailers for every RPC handled by a [Server], keyed by
+// fully-qualified method name.
+//
+// A call is recorded as soon as its interceptor observes it, before the
+// handler runs, so that [MetadataRecorder.WaitFor] can synchronise with a
+// streaming RPC that is still in flight; Header and Trailer are filled in
+// once the handler returns.
+//
+// Its interceptor methods compose with any user-provided interceptors passed
+// to [NewServer] via grpc.ChainUnaryInterceptor and grpc.ChainStreamInterceptor:
+//
+//	rec := grpctest.NewMetadataRecorder()
+//	s := grpctest.NewServer(
+//	    grpc.ChainUnaryInterceptor(rec.UnaryServerInterceptor(), myInterceptor),
+//	    grpc.ChainStreamInterceptor(rec.StreamServerInterceptor()),
+//	)
+type MetadataRecorder struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	calls []*RecordedCall
+}
+
+// NewMetadataRecorder creates a MetadataRecorder ready to be installed on a
+// [Server] via its interceptor methods.
+func NewMetadataRecorder() *MetadataRecorder {
+	r := &MetadataRecorder{}
+	r.cond = sync.NewCond(&r.mu)
+	return r
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that records
+// the incoming metadata and peer of each unary RPC as soon as it arrives,
+// then fills in any header and trailer metadata the handler sends once it
+// returns.
+func (r *MetadataRecorder) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		pr, _ := peer.FromContext(ctx)
+		call := r.open(info.FullMethod, pr, md)
+
+		var header, trailer metadata.MD
+		if sts := grpc.ServerTransportStreamFromContext(ctx); sts != nil {
+			ctx = grpc.NewContextWithServerTransportStream(ctx, &recordingTransportStream{
+				ServerTransportStream: sts,
+				header:                &header,
+				trailer:               &trailer,
+			})
+		}
+
+		resp, err := handler(ctx, req)
+		r.complete(call, header, trailer)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that records
+// the incoming metadata and peer of each streaming RPC as soon as it opens,
+// then fills in any header and trailer metadata the handler sends once it
+// returns.
+func (r *MetadataRecorder) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		md, _ := metadata.FromIncomingContext(ctx)
+		pr, _ := peer.FromContext(ctx)
+		call := r.open(info.FullMethod, pr, md)
+
+		var header, trailer metadata.MD
+		wrapped := &recordingServerStream{ServerStream: ss, header: &header, trailer: &trailer}
+
+		err := handler(srv, wrapped)
+		r.complete(call, header, trailer)
+
+		return err
+	}
+}
+
+// Calls returns every RPC recorded so far, in the order they were observed.
+// An RPC still in flight appears with a zero Header and Trailer.
+func (r *MetadataRecorder) Calls() []RecordedCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := make([]RecordedCall, len(r.calls))
+	for i, c := range r.calls {
+		calls[i] = *c
+	}
+	return calls
+}
+
+// LastMD returns the incoming metadata of the most recently observed call to
+// method, or nil if no such call has been recorded.
+func (r *MetadataRecorder) LastMD(method string) metadata.MD {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.calls) - 1; i >= 0; i-- {
+		if r.calls[i].Method == method {
+			return r.calls[i].MD
+		}
+	}
+	return nil
+}
+
+// WaitFor blocks until at least one call to method has been observed, or
+// timeout elapses, returning the most recently observed such call. Calls are
+// recorded as soon as their interceptor runs, before the handler does, so
+// WaitFor can synchronise with a streaming or otherwise long-running RPC that
+// is still in flight; in that case the returned call's Header and Trailer may
+// still be zero.
+func (r *MetadataRecorder) WaitFor(method string, timeout time.Duration) (RecordedCall, error) {
+	deadline := time.Now().Add(timeout)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for {
+		for i := len(r.calls) - 1; i >= 0; i-- {
+			if r.calls[i].Method == method {
+				return *r.calls[i], nil
+			}
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return RecordedCall{}, fmt.Errorf("grpctest: timed out after %s waiting for a call to %s", timeout, method)
+		}
+
+		timer := time.AfterFunc(remaining, r.cond.Broadcast)
+		r.cond.Wait()
+		timer.Stop()
+	}
+}
+
+// open records the start of a call, before its handler has run, and returns
+// it so complete can fill in its Header and Trailer afterwards.
+func (r *MetadataRecorder) open(method string, pr *peer.Peer, md metadata.MD) *RecordedCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	call := &RecordedCall{Method: method, Peer: pr, MD: md}
+	r.calls = append(r.calls, call)
+	r.cond.Broadcast()
+	return call
+}
+
+// complete fills in the header and trailer metadata a handler sent once it
+// returns.
+func (r *MetadataRecorder) complete(call *RecordedCall, header, trailer metadata.MD) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	call.Header = header
+	call.Trailer = trailer
+	r.cond.Broadcast()
+}
+
+// recordingTransportStream wraps a grpc.ServerTransportStream to capture the
+// header and trailer metadata sent by a unary handler.
+type recordingTransportStream struct {
+	grpc.ServerTransportStream
+
+	header  *metadata.MD
+	trailer *metadata.MD
+}
+
+func (s *recordingTransportStream) SetHeader(md metadata.MD) error {
+	*s.header = metadata.Join(*s.header, md)
+	return s.ServerTransportStream.SetHeader(md)
+}
+
+func (s *recordingTransportStream) SendHeader(md metadata.MD) error {
+	*s.header = metadata.Join(*s.header, md)
+	return s.ServerTransportStream.SendHeader(md)
+}
+
+func (s *recordingTransportStream) SetTrailer(md metadata.MD) error {
+	*s.trailer = metadata.Join(*s.trailer, md)
+	return s.ServerTransportStream.SetTrailer(md)
+}
+
+// recordingServerStream wraps a grpc.ServerStream to capture the header and
+// trailer metadata sent by a streaming handler.
+type recordingServerStream struct {
+	grpc.ServerStream
+
+	header  *metadata.MD
+	trailer *metadata.MD
+}
+
+func (s *recordingServerStream) SetHeader(md metadata.MD) error {
+	*s.header = metadata.Join(*s.header, md)
+	return s.ServerStream.SetHeader(md)
+}
+
+func (s *recordingServerStream) SendHeader(md metadata.MD) error {
+	*s.header = metadata.Join(*s.header, md)
+	return s.ServerStream.SendHeader(md)
+}
+
+func (s *recordingServerStream) SetTrailer(md metadata.MD) {
+	*s.trailer = metadata.Join(*s.trailer, md)
+	s.ServerStream.SetTrailer(md)
+}
+
+// ClientMetadataRecorder is the client-side counterpart to MetadataRecorder.
+// It captures the outgoing metadata, and the header and trailer metadata
+// received in response, for every unary RPC made on a [grpc.ClientConn].
+// It has no stream interceptor, so streaming RPCs are not recorded.
+type ClientMetadataRecorder struct {
+	mu    sync.Mutex
+	calls []RecordedCall
+}
+
+// NewClientMetadataRecorder creates a ClientMetadataRecorder ready to be
+// installed on a client connection via its interceptor methods.
+func NewClientMetadataRecorder() *ClientMetadataRecorder {
+	return &ClientMetadataRecorder{}
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that records
+// the outgoing metadata and the header/trailer metadata returned for each
+// unary RPC.
+func (r *ClientMetadataRecorder) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+
+		var header, trailer metadata.MD
+		opts = append(opts, grpc.Header(&header), grpc.Trailer(&trailer))
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		r.record(RecordedCall{
+			Method:  method,
+			MD:      md,
+			Header:  header,
+			Trailer: trailer,
+		})
+
+		return err
+	}
+}
+
+// Calls returns every RPC recorded so far, in the order they completed.
+func (r *ClientMetadataRecorder) Calls() []RecordedCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := make([]RecordedCall, len(r.calls))
+	copy(calls, r.calls)
+	return calls
+}
+
+// LastMD returns the outgoing metadata of the most recently completed call to
+// method, or nil if no such call has been recorded.
+func (r *ClientMetadataRecorder) LastMD(method string) metadata.MD {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.calls) - 1; i >= 0; i-- {
+		if r.calls[i].Method == method {
+			return r.calls[i].MD
+		}
+	}
+	return nil
+}
+
+func (r *ClientMetadataRecorder) record(c RecordedCall) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, c)
+}
+
+// ClientConnWithRecorder is identical to [Server.ClientConn] but additionally
+// installs a [ClientMetadataRecorder], returning it alongside the connection.
+func (s *Server) ClientConnWithRecorder(opts ...grpc.DialOption) (*grpc.ClientConn, *ClientMetadataRecorder, error) {
+	return s.ClientConnContextWithRecorder(context.Background(), opts...)
+}
+
+// ClientConnContextWithRecorder is identical to [Server.ClientConnContext] but
+// additionally installs a [ClientMetadataRecorder], returning it alongside the
+// connection.
+func (s *Server) ClientConnContextWithRecorder(ctx context.Context, opts ...grpc.DialOption) (*grpc.ClientConn, *ClientMetadataRecorder, error) {
+	rec := NewClientMetadataRecorder()
+
+	opts = append([]grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(rec.UnaryClientInterceptor()),
+	}, opts...)
+
+	conn, err := s.ClientConnContext(ctx, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conn, rec, nil
+}
@@ -0,0 +1,93 @@
+package grpctest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tomasbasham/grpctest"
+	echopb "github.com/tomasbasham/grpctest/testdata/echo/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestMetadataRecorder(t *testing.T) {
+	t.Parallel()
+
+	rec := grpctest.NewMetadataRecorder()
+
+	s := grpctest.NewServer(
+		grpc.ChainUnaryInterceptor(rec.UnaryServerInterceptor()),
+	)
+	s.CloseOnCleanup(t)
+
+	echopb.RegisterEchoServiceServer(s, &echoServer{})
+	s.Serve()
+
+	conn, err := s.ClientConn()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	const method = "/echo.v1.EchoService/Echo"
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-test-token", "secret")
+	client := echopb.NewEchoServiceClient(conn)
+	if _, err := client.Echo(ctx, &echopb.EchoRequest{Message: "hi"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	md := rec.LastMD(method)
+	if got := md.Get("x-test-token"); len(got) != 1 || got[0] != "secret" {
+		t.Errorf("LastMD(%q) = %v, want x-test-token=secret", method, md)
+	}
+
+	calls := rec.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("Calls() returned %d entries, want 1", len(calls))
+	}
+	if calls[0].Method != method {
+		t.Errorf("Calls()[0].Method = %q, want %q", calls[0].Method, method)
+	}
+
+	call, err := rec.WaitFor(method, time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if call.Method != method {
+		t.Errorf("WaitFor(%q).Method = %q, want %q", method, call.Method, method)
+	}
+}
+
+func TestMetadataRecorder_WaitForTimesOut(t *testing.T) {
+	t.Parallel()
+
+	rec := grpctest.NewMetadataRecorder()
+
+	_, err := rec.WaitFor("/echo.v1.EchoService/Echo", 50*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected WaitFor to time out, got nil error")
+	}
+}
+
+func TestClientConnWithRecorder(t *testing.T) {
+	t.Parallel()
+
+	s := grpctest.NewServer()
+	s.CloseOnCleanup(t)
+
+	echopb.RegisterEchoServiceServer(s, &echoServer{})
+	s.Serve()
+
+	conn, rec, err := s.ClientConnWithRecorder()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mustEcho(t, conn, "client recorder")
+
+	const method = "/echo.v1.EchoService/Echo"
+	if calls := rec.Calls(); len(calls) != 1 || calls[0].Method != method {
+		t.Errorf("Calls() = %#v, want a single call to %q", calls, method)
+	}
+}
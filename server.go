@@ -2,6 +2,7 @@ package grpctest
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"sync"
 	"sync/atomic"
@@ -11,7 +12,6 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/grpc/test/bufconn"
 )
 
 // bufferSize is the default size of the buffered connection.
@@ -31,22 +31,50 @@ func getBufferSize() int {
 	return int(atomic.LoadInt32(&bufferSize))
 }
 
-// Server is a gRPC server listening on a buffered in-memory connection.
+// Server is a gRPC server listening on a pluggable test [Transport], which
+// defaults to a buffered in-memory connection.
 type Server struct {
 	*grpc.Server
 
-	listener  *bufconn.Listener
+	transport Transport
+	listener  net.Listener
+	streams   *streamTracker
 	once      sync.Once
 	serveErr  error
 	serveDone chan struct{}
 }
 
-// NewServer creates a new in-memory test gRPC server. Services must be
-// registered before calling [Server.Serve].
+// NewServer creates a new in-memory test gRPC server using the default
+// bufconn [Transport]. Services must be registered before calling
+// [Server.Serve].
 func NewServer(opts ...grpc.ServerOption) *Server {
+	return NewServerWithTransport(NewBufconnTransport(), opts...)
+}
+
+// NewServerWithTransport creates a new test gRPC server listening on the
+// given [Transport]. Services must be registered before calling
+// [Server.Serve].
+//
+// It panics if the transport fails to create its listener, since this always
+// indicates a usage error (such as an unwritable temp directory) rather than
+// a condition a test should need to recover from.
+func NewServerWithTransport(transport Transport, opts ...grpc.ServerOption) *Server {
+	l, err := transport.listen()
+	if err != nil {
+		panic(fmt.Sprintf("grpctest: failed to create listener: %v", err))
+	}
+
+	streams := newStreamTracker()
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(recoverUnimplementedUnary),
+		grpc.ChainStreamInterceptor(recoverUnimplementedStream, streams.interceptor()),
+	}, opts...)
+
 	return &Server{
 		Server:    grpc.NewServer(opts...),
-		listener:  bufconn.Listen(getBufferSize()),
+		transport: transport,
+		listener:  l,
+		streams:   streams,
 		serveDone: make(chan struct{}),
 	}
 }
@@ -88,31 +116,47 @@ func (s *Server) CloseOnCleanup(t testing.TB) {
 	t.Cleanup(s.Close)
 }
 
+// Addr returns the address the server is listening on, as reported directly
+// by its [Transport]'s listener (e.g. "bufconn" for the default bufconn
+// transport, a unix socket path, or a "127.0.0.1:PORT" loopback address). To
+// get the target a client should dial instead, use [Server.DialTarget].
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// DialTarget returns the gRPC target string a client should dial to reach
+// this server, as determined by its [Transport]. This is the same target
+// [Server.ClientConn] uses internally.
+func (s *Server) DialTarget() string {
+	target, _ := s.transport.dialTarget(s.listener)
+	return target
+}
+
 // ClientConn returns a gRPC client connection to the test server.
 //
-// The connection is configured to dial the server's in-memory listener.
-// Additional [grpc.DialOptions] may be provided but the ContextDialer is fixed
-// and cannot be overridden.
+// The connection is configured to dial the server's listener, using the
+// target and dial options required by its [Transport]. Additional
+// [grpc.DialOptions] may be provided but the ones required to reach the
+// transport are fixed and cannot be overridden.
 func (s *Server) ClientConn(opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	return s.ClientConnContext(context.Background(), opts...)
 }
 
 // ClientConnContext returns a gRPC client connection to the test server.
 //
-// The connection is configured to dial the server's in-memory listener.
-// Additional [grpc.DialOptions] may be provided but the ContextDialer is fixed
-// and cannot be overridden.
+// The connection is configured to dial the server's listener, using the
+// target and dial options required by its [Transport]. Additional
+// [grpc.DialOptions] may be provided but the ones required to reach the
+// transport are fixed and cannot be overridden.
 func (s *Server) ClientConnContext(ctx context.Context, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	target, transportOpts := s.transport.dialTarget(s.listener)
+
 	opts = append([]grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 	}, opts...)
+	opts = append(opts, transportOpts...)
 
-	// Use a custom dialer that dials the bufconn listener.
-	opts = append(opts, grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
-		return s.listener.Dial()
-	}))
-
-	conn, err := grpc.NewClient("passthrough:///test", opts...)
+	conn, err := grpc.NewClient(target, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,68 @@
+package grpctest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"testing"
+	"time"
+)
+
+// ErrForcedShutdown is returned by [Server.Shutdown] when the provided
+// context expires before in-flight RPCs drain, forcing a hard [Server.Stop].
+var ErrForcedShutdown = errors.New("grpctest: graceful shutdown deadline exceeded, server was stopped forcibly")
+
+// Shutdown gracefully stops the server, allowing in-flight RPCs to complete.
+// It calls [grpc.Server.GracefulStop] and waits for it to return or for ctx to
+// be done, whichever comes first. If ctx is done before the graceful stop
+// completes, Shutdown falls back to [Server.Stop] and returns
+// [ErrForcedShutdown].
+func (s *Server) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		s.listener.Close()
+		return nil
+	case <-ctx.Done():
+		s.Close()
+		return ErrForcedShutdown
+	}
+}
+
+// CloseGracefullyOnCleanup registers the server to be shut down gracefully
+// when the test ends, allowing in-flight RPCs up to timeout to complete
+// before falling back to a hard stop. If the deadline expires and the server
+// has to be force-stopped, the test is failed with [ErrForcedShutdown] rather
+// than letting the abrupt stop mask a bug in a handler's shutdown behaviour.
+func (s *Server) CloseGracefullyOnCleanup(t testing.TB, timeout time.Duration) {
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		if err := s.Shutdown(ctx); err != nil {
+			t.Errorf("grpctest: graceful shutdown failed: %v", err)
+		}
+	})
+}
+
+// RunUntilSignal blocks until one of the given signals is received, then
+// gracefully shuts the server down. It is intended for example binaries (such
+// as those under examples/) that want to terminate cleanly on SIGINT or
+// SIGTERM without hand-rolling signal plumbing.
+func (s *Server) RunUntilSignal(sig ...os.Signal) error {
+	ctx, stop := signal.NotifyContext(context.Background(), sig...)
+	defer stop()
+
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return s.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,91 @@
+package grpctest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tomasbasham/grpctest"
+	echopb "github.com/tomasbasham/grpctest/testdata/echo/v1"
+)
+
+func TestServer_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	t.Run("drains in-flight RPCs", func(t *testing.T) {
+		t.Parallel()
+
+		s := grpctest.NewServer()
+		echopb.RegisterEchoServiceServer(s, &echoServer{})
+		s.Serve()
+
+		conn, err := s.ClientConn()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		mustEcho(t, conn, "before shutdown")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := s.Shutdown(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("forces a stop when the deadline expires", func(t *testing.T) {
+		t.Parallel()
+
+		s := grpctest.NewServer()
+		echopb.RegisterEchoServiceServer(s, &blockingEchoServer{release: make(chan struct{})})
+		s.Serve()
+
+		conn, err := s.ClientConn()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		client := echopb.NewEchoServiceClient(conn)
+		go client.Echo(context.Background(), &echopb.EchoRequest{Message: "stuck"})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err = s.Shutdown(ctx)
+		if !errors.Is(err, grpctest.ErrForcedShutdown) {
+			t.Fatalf("expected ErrForcedShutdown, got: %v", err)
+		}
+	})
+}
+
+func TestServer_CloseGracefullyOnCleanup(t *testing.T) {
+	t.Parallel()
+
+	s := grpctest.NewServer()
+	s.CloseGracefullyOnCleanup(t, 5*time.Second)
+
+	echopb.RegisterEchoServiceServer(s, &echoServer{})
+	s.Serve()
+
+	conn, err := s.ClientConn()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mustEcho(t, conn, "graceful cleanup")
+}
+
+// blockingEchoServer blocks Echo until release is closed, used to simulate an
+// in-flight RPC that outlives a shutdown deadline.
+type blockingEchoServer struct {
+	echopb.UnimplementedEchoServiceServer
+
+	release chan struct{}
+}
+
+func (s *blockingEchoServer) Echo(ctx context.Context, in *echopb.EchoRequest) (*echopb.EchoResponse, error) {
+	<-s.release
+	return &echopb.EchoResponse{Message: in.Message}, nil
+}
@@ -0,0 +1,204 @@
+package grpctest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// CollectServerStream reads responses from stream until it has collected max
+// of them, the stream ends with io.EOF, or the stream errors, whichever comes
+// first. On io.EOF it returns the responses collected so far with a nil
+// error.
+//
+// If timeout elapses before the stream yields max responses, the responses
+// collected so far are returned alongside context.DeadlineExceeded. The
+// background goroutine reading from stream is left blocked in Recv until the
+// stream's context is cancelled or the server closes it; callers that need
+// the read aborted promptly should cancel that context on timeout.
+func CollectServerStream[Resp any](stream grpc.ServerStreamingClient[Resp], max int, timeout time.Duration) ([]Resp, error) {
+	type result struct {
+		resp Resp
+		err  error
+	}
+
+	results := make(chan result)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(results)
+		for {
+			resp, err := stream.Recv()
+			r := result{err: err}
+			if err == nil {
+				r.resp = *resp
+			}
+			select {
+			case results <- r:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	responses := make([]Resp, 0, max)
+	for len(responses) < max {
+		select {
+		case r, ok := <-results:
+			if !ok || errors.Is(r.err, io.EOF) {
+				return responses, nil
+			}
+			if r.err != nil {
+				return responses, r.err
+			}
+			responses = append(responses, r.resp)
+		case <-timer.C:
+			return responses, context.DeadlineExceeded
+		}
+	}
+	return responses, nil
+}
+
+// SendAll sends each of reqs on stream in order, then calls CloseAndRecv to
+// signal no more requests and retrieve the RPC's single response. It stops at
+// the first error encountered, whether from a Send or from CloseAndRecv.
+func SendAll[Req, Resp any](stream grpc.ClientStreamingClient[Req, Resp], reqs []Req) (*Resp, error) {
+	for i := range reqs {
+		if err := stream.Send(&reqs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+// BidiDirection distinguishes a sent message from a received one in a
+// [BidiRecorder]'s event log.
+type BidiDirection int
+
+const (
+	BidiSent BidiDirection = iota
+	BidiReceived
+)
+
+// String returns "sent" or "received".
+func (d BidiDirection) String() string {
+	if d == BidiSent {
+		return "sent"
+	}
+	return "received"
+}
+
+// BidiEvent is a single recorded send or receive observed by a
+// [BidiRecorder].
+type BidiEvent struct {
+	Direction BidiDirection
+	Message   any
+}
+
+// BidiRecorder records the ordered sequence of messages sent and received on
+// one end of a bidirectional streaming RPC, for tests that need to assert on
+// interleaving rather than just final state. A test wraps its own
+// stream.Send/stream.Recv calls with RecordSent/RecordReceived; client and
+// server sides typically each keep their own BidiRecorder.
+type BidiRecorder struct {
+	mu     sync.Mutex
+	events []BidiEvent
+}
+
+// NewBidiRecorder creates an empty BidiRecorder.
+func NewBidiRecorder() *BidiRecorder {
+	return &BidiRecorder{}
+}
+
+// RecordSent appends a sent message to the event log.
+func (r *BidiRecorder) RecordSent(msg any) {
+	r.append(BidiEvent{Direction: BidiSent, Message: msg})
+}
+
+// RecordReceived appends a received message to the event log.
+func (r *BidiRecorder) RecordReceived(msg any) {
+	r.append(BidiEvent{Direction: BidiReceived, Message: msg})
+}
+
+func (r *BidiRecorder) append(e BidiEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+// Events returns the ordered sequence of recorded sends and receives.
+func (r *BidiRecorder) Events() []BidiEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]BidiEvent, len(r.events))
+	copy(events, r.events)
+	return events
+}
+
+// streamTracker counts, per fully-qualified method, how many streams have
+// opened on a [Server], waking any goroutines blocked in
+// [Server.WaitForStream].
+type streamTracker struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	opened map[string]int
+}
+
+func newStreamTracker() *streamTracker {
+	t := &streamTracker{opened: make(map[string]int)}
+	t.cond = sync.NewCond(&t.mu)
+	return t
+}
+
+// interceptor returns the grpc.StreamServerInterceptor a [Server] installs
+// internally to observe stream opens. It is always chained ahead of any
+// interceptors a caller passes to [NewServer].
+func (t *streamTracker) interceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		t.mu.Lock()
+		t.opened[info.FullMethod]++
+		t.cond.Broadcast()
+		t.mu.Unlock()
+
+		return handler(srv, ss)
+	}
+}
+
+func (t *streamTracker) waitFor(method string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for t.opened[method] == 0 {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("grpctest: timed out after %s waiting for a stream to open on %s", timeout, method)
+		}
+
+		timer := time.AfterFunc(remaining, t.cond.Broadcast)
+		t.cond.Wait()
+		timer.Stop()
+	}
+	return nil
+}
+
+// WaitForStream blocks until at least one streaming RPC to method has opened,
+// or timeout elapses. It is useful for synchronising a test with a
+// server-side stream handler before injecting a shutdown or a peer
+// disconnect.
+func (s *Server) WaitForStream(method string, timeout time.Duration) error {
+	return s.streams.waitFor(method, timeout)
+}
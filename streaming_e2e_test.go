@@ -0,0 +1,324 @@
+package grpctest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tomasbasham/grpctest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// This file wires up a tiny hand-written streaming service, bypassing
+// protoc-gen-go entirely, so that CollectServerStream, BidiRecorder and
+// Server.WaitForStream can be exercised end to end against a real
+// grpctest.Server without depending on generated protobuf code.
+
+const testServiceName = "grpctest.testing.TestService"
+
+// stringCodec is a minimal encoding.Codec that marshals a *string as its raw
+// bytes, letting this fixture avoid depending on protobuf-generated types.
+type stringCodec struct{}
+
+func (stringCodec) Marshal(v any) ([]byte, error) {
+	s, ok := v.(*string)
+	if !ok {
+		return nil, fmt.Errorf("stringCodec: unsupported type %T", v)
+	}
+	return []byte(*s), nil
+}
+
+func (stringCodec) Unmarshal(data []byte, v any) error {
+	s, ok := v.(*string)
+	if !ok {
+		return fmt.Errorf("stringCodec: unsupported type %T", v)
+	}
+	*s = string(data)
+	return nil
+}
+
+func (stringCodec) Name() string { return "grpctest-string" }
+
+func init() {
+	encoding.RegisterCodec(stringCodec{})
+}
+
+// testServiceIface is the interface a registered implementation must satisfy,
+// mirroring what protoc-gen-go-grpc would generate for a service with one
+// server-streaming and one bidi-streaming method.
+type testServiceIface interface {
+	Count(req *string, stream grpc.ServerStreamingServer[string]) error
+	EchoBidi(stream grpc.BidiStreamingServer[string, string]) error
+}
+
+var testServiceDesc = grpc.ServiceDesc{
+	ServiceName: testServiceName,
+	HandlerType: (*testServiceIface)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Count",
+			Handler:       countStreamHandler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "EchoBidi",
+			Handler:       echoBidiStreamHandler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+}
+
+type countServerStream struct{ grpc.ServerStream }
+
+func (x *countServerStream) Send(m *string) error { return x.ServerStream.SendMsg(m) }
+
+func countStreamHandler(srv any, stream grpc.ServerStream) error {
+	req := new(string)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(testServiceIface).Count(req, &countServerStream{stream})
+}
+
+type echoBidiServerStream struct{ grpc.ServerStream }
+
+func (x *echoBidiServerStream) Send(m *string) error { return x.ServerStream.SendMsg(m) }
+
+func (x *echoBidiServerStream) Recv() (*string, error) {
+	m := new(string)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func echoBidiStreamHandler(srv any, stream grpc.ServerStream) error {
+	return srv.(testServiceIface).EchoBidi(&echoBidiServerStream{stream})
+}
+
+// testService is the hand-written implementation registered with the server
+// under test.
+type testService struct {
+	countFn func(req *string, stream grpc.ServerStreamingServer[string]) error
+	bidiRec *grpctest.BidiRecorder
+}
+
+func (s *testService) Count(req *string, stream grpc.ServerStreamingServer[string]) error {
+	return s.countFn(req, stream)
+}
+
+func (s *testService) EchoBidi(stream grpc.BidiStreamingServer[string, string]) error {
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if s.bidiRec != nil {
+			s.bidiRec.RecordReceived(*req)
+		}
+
+		resp := strings.ToUpper(*req)
+		if err := stream.Send(&resp); err != nil {
+			return err
+		}
+		if s.bidiRec != nil {
+			s.bidiRec.RecordSent(resp)
+		}
+	}
+}
+
+// countClientStream adapts a grpc.ClientStream into a
+// grpc.ServerStreamingClient[string], as protoc-gen-go-grpc would generate.
+type countClientStream struct{ grpc.ClientStream }
+
+func (x *countClientStream) Recv() (*string, error) {
+	m := new(string)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func dialCountStream(t *testing.T, conn *grpc.ClientConn, req string) grpc.ServerStreamingClient[string] {
+	t.Helper()
+
+	stream, err := conn.NewStream(
+		context.Background(),
+		&grpc.StreamDesc{StreamName: "Count", ServerStreams: true},
+		"/"+testServiceName+"/Count",
+		grpc.ForceCodec(stringCodec{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	x := &countClientStream{stream}
+	if err := x.SendMsg(&req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := x.CloseSend(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return x
+}
+
+// echoBidiClientStream adapts a grpc.ClientStream into a
+// grpc.BidiStreamingClient[string, string].
+type echoBidiClientStream struct{ grpc.ClientStream }
+
+func (x *echoBidiClientStream) Send(m *string) error { return x.ClientStream.SendMsg(m) }
+
+func (x *echoBidiClientStream) Recv() (*string, error) {
+	m := new(string)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func dialEchoBidiStream(t *testing.T, conn *grpc.ClientConn) grpc.BidiStreamingClient[string, string] {
+	t.Helper()
+
+	stream, err := conn.NewStream(
+		context.Background(),
+		&grpc.StreamDesc{StreamName: "EchoBidi", ServerStreams: true, ClientStreams: true},
+		"/"+testServiceName+"/EchoBidi",
+		grpc.ForceCodec(stringCodec{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &echoBidiClientStream{stream}
+}
+
+func TestServer_StreamingEndToEnd(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+
+	s := grpctest.NewServer()
+	s.CloseOnCleanup(t)
+	s.RegisterService(&testServiceDesc, &testService{
+		countFn: func(req *string, stream grpc.ServerStreamingServer[string]) error {
+			<-release
+			for i := 0; i < 3; i++ {
+				msg := fmt.Sprintf("%s-%d", *req, i)
+				if err := stream.Send(&msg); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+	s.Serve()
+
+	conn, err := s.ClientConn()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clientStream := dialCountStream(t, conn, "item")
+
+	method := "/" + testServiceName + "/Count"
+	if err := s.WaitForStream(method, 5*time.Second); err != nil {
+		t.Fatalf("WaitForStream did not observe the open stream: %v", err)
+	}
+	close(release)
+
+	got, err := grpctest.CollectServerStream[string](clientStream, 3, 5*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"item-0", "item-1", "item-2"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestBidiRecorder_EndToEnd(t *testing.T) {
+	t.Parallel()
+
+	serverRec := grpctest.NewBidiRecorder()
+	clientRec := grpctest.NewBidiRecorder()
+
+	s := grpctest.NewServer()
+	s.CloseOnCleanup(t)
+	s.RegisterService(&testServiceDesc, &testService{bidiRec: serverRec})
+	s.Serve()
+
+	conn, err := s.ClientConn()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stream := dialEchoBidiStream(t, conn)
+
+	for _, msg := range []string{"a", "b", "c"} {
+		req := msg
+		if err := stream.Send(&req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		clientRec.RecordSent(req)
+
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		clientRec.RecordReceived(*resp)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Wait for the handler to return so its last RecordSent has happened.
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF after CloseSend, got: %v", err)
+	}
+
+	wantServer := []grpctest.BidiDirection{
+		grpctest.BidiReceived, grpctest.BidiSent,
+		grpctest.BidiReceived, grpctest.BidiSent,
+		grpctest.BidiReceived, grpctest.BidiSent,
+	}
+	serverEvents := serverRec.Events()
+	if len(serverEvents) != len(wantServer) {
+		t.Fatalf("server recorded %d events, want %d", len(serverEvents), len(wantServer))
+	}
+	for i, want := range wantServer {
+		if serverEvents[i].Direction != want {
+			t.Errorf("serverEvents[%d].Direction = %v, want %v", i, serverEvents[i].Direction, want)
+		}
+	}
+
+	wantClient := []grpctest.BidiDirection{
+		grpctest.BidiSent, grpctest.BidiReceived,
+		grpctest.BidiSent, grpctest.BidiReceived,
+		grpctest.BidiSent, grpctest.BidiReceived,
+	}
+	clientEvents := clientRec.Events()
+	if len(clientEvents) != len(wantClient) {
+		t.Fatalf("client recorded %d events, want %d", len(clientEvents), len(wantClient))
+	}
+	for i, want := range wantClient {
+		if clientEvents[i].Direction != want {
+			t.Errorf("clientEvents[%d].Direction = %v, want %v", i, clientEvents[i].Direction, want)
+		}
+	}
+	if clientEvents[1].Message.(string) != "A" {
+		t.Errorf("clientEvents[1].Message = %v, want %q", clientEvents[1].Message, "A")
+	}
+}
@@ -0,0 +1,167 @@
+package grpctest_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/tomasbasham/grpctest"
+	"google.golang.org/grpc"
+)
+
+// fakeServerStream is a minimal grpc.ServerStreamingClient[Resp] backed by an
+// in-memory slice, used to exercise CollectServerStream without a generated
+// streaming RPC.
+type fakeServerStream[Resp any] struct {
+	grpc.ClientStream
+
+	responses []*Resp
+	err       error
+}
+
+func (f *fakeServerStream[Resp]) Recv() (*Resp, error) {
+	if len(f.responses) == 0 {
+		if f.err != nil {
+			return nil, f.err
+		}
+		return nil, io.EOF
+	}
+
+	resp := f.responses[0]
+	f.responses = f.responses[1:]
+	return resp, nil
+}
+
+func TestCollectServerStream(t *testing.T) {
+	t.Parallel()
+
+	t.Run("collects up to max responses", func(t *testing.T) {
+		t.Parallel()
+
+		a, b, c := "a", "b", "c"
+		stream := &fakeServerStream[string]{responses: []*string{&a, &b, &c}}
+
+		got, err := grpctest.CollectServerStream[string](stream, 2, time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := []string{"a", "b"}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("stops at io.EOF", func(t *testing.T) {
+		t.Parallel()
+
+		a := "only"
+		stream := &fakeServerStream[string]{responses: []*string{&a}}
+
+		got, err := grpctest.CollectServerStream[string](stream, 5, time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 1 || got[0] != "only" {
+			t.Errorf("got %v, want [only]", got)
+		}
+	})
+
+	t.Run("returns the stream's error", func(t *testing.T) {
+		t.Parallel()
+
+		wantErr := errors.New("boom")
+		stream := &fakeServerStream[string]{err: wantErr}
+
+		_, err := grpctest.CollectServerStream[string](stream, 5, time.Second)
+		if !errors.Is(err, wantErr) {
+			t.Errorf("got %v, want %v", err, wantErr)
+		}
+	})
+}
+
+// fakeClientStream is a minimal grpc.ClientStreamingClient[Req, Resp] backed
+// by in-memory slices, used to exercise SendAll without a generated streaming
+// RPC.
+type fakeClientStream[Req, Resp any] struct {
+	grpc.ClientStream
+
+	sendErr  error
+	response *Resp
+	sent     []Req
+}
+
+func (f *fakeClientStream[Req, Resp]) Send(req *Req) error {
+	if f.sendErr != nil {
+		return f.sendErr
+	}
+	f.sent = append(f.sent, *req)
+	return nil
+}
+
+func (f *fakeClientStream[Req, Resp]) CloseAndRecv() (*Resp, error) {
+	return f.response, nil
+}
+
+func TestSendAll(t *testing.T) {
+	t.Parallel()
+
+	want := "done"
+	stream := &fakeClientStream[string, string]{response: &want}
+
+	got, err := grpctest.SendAll[string, string](stream, []string{"one", "two", "three"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || *got != want {
+		t.Errorf("got %v, want %q", got, want)
+	}
+	if len(stream.sent) != 3 {
+		t.Errorf("sent %d requests, want 3", len(stream.sent))
+	}
+}
+
+func TestSendAll_StopsOnSendError(t *testing.T) {
+	t.Parallel()
+
+	wantErr := errors.New("send failed")
+	stream := &fakeClientStream[string, string]{sendErr: wantErr}
+
+	_, err := grpctest.SendAll[string, string](stream, []string{"one"})
+	if !errors.Is(err, wantErr) {
+		t.Errorf("got %v, want %v", err, wantErr)
+	}
+}
+
+func TestBidiRecorder(t *testing.T) {
+	t.Parallel()
+
+	r := grpctest.NewBidiRecorder()
+	r.RecordSent("ping")
+	r.RecordReceived("pong")
+	r.RecordSent("ping2")
+
+	events := r.Events()
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+
+	want := []grpctest.BidiDirection{grpctest.BidiSent, grpctest.BidiReceived, grpctest.BidiSent}
+	for i, e := range events {
+		if e.Direction != want[i] {
+			t.Errorf("events[%d].Direction = %v, want %v", i, e.Direction, want[i])
+		}
+	}
+}
+
+func TestServer_WaitForStream_TimesOut(t *testing.T) {
+	t.Parallel()
+
+	s := grpctest.NewServer()
+	s.CloseOnCleanup(t)
+	s.Serve()
+
+	err := s.WaitForStream("/echo.v1.EchoService/NoSuchStream", 50*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected WaitForStream to time out, got nil error")
+	}
+}
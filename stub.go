@@ -0,0 +1,169 @@
+package grpctest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Stub wraps a stub value, such as a generated *Stub type implementing a
+// service interface via function fields, marking it ready for registration
+// with a [Server].
+//
+// Stub exists purely as a signal to readers: the argument is not a full
+// service implementation but a per-test stand-in where each RPC is backed by
+// an optional function field, e.g.:
+//
+//	type EchoServiceStub struct {
+//	    EchoFn func(ctx context.Context, req *EchoRequest) (*EchoResponse, error)
+//	}
+//
+//	func (s *EchoServiceStub) Echo(ctx context.Context, req *EchoRequest) (*EchoResponse, error) {
+//	    return grpctest.InvokeUnary[EchoRequest, EchoResponse](s, "Echo", ctx, req)
+//	}
+//
+// Each forwarding method calls [InvokeUnary] or [InvokeStream] rather than
+// hand-rolling a nil check, so the panic-on-unset dispatch lives in this
+// package instead of being duplicated per RPC.
+func Stub[T any](s T) T {
+	return s
+}
+
+// Unimplemented panics with a value identifying the missing RPC. It is called
+// by [InvokeUnary] and [InvokeStream] when the looked-up function field is
+// nil, so that invoking an RPC a test did not configure fails loudly rather
+// than silently returning a zero value.
+//
+// grpc-go does not recover panics raised from within a handler, so a stub
+// method invoked over a real RPC would otherwise crash the whole test binary.
+// The unary and stream interceptors [NewServer] installs by default recover
+// this specific panic and turn it into a codes.Unimplemented status error, so
+// only the offending RPC fails; any other panic is left to propagate as
+// before.
+func Unimplemented(method string) {
+	panic(unimplementedPanic{method: method})
+}
+
+// unimplementedPanic is the panic value [Unimplemented] raises. Its own type
+// (rather than a string or error) lets the recovery interceptors installed by
+// [NewServer] distinguish it from an unrelated panic without resorting to
+// matching on a message string.
+type unimplementedPanic struct {
+	method string
+}
+
+func (p unimplementedPanic) Error() string {
+	return fmt.Sprintf("grpctest: stub method %s was invoked but no function field was set", p.method)
+}
+
+// recoverUnimplementedUnary is the default grpc.UnaryServerInterceptor
+// installed by [NewServer]. It recovers a panic raised by [Unimplemented],
+// converting it into a codes.Unimplemented status error; any other panic is
+// re-raised unchanged.
+func recoverUnimplementedUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			p, ok := r.(unimplementedPanic)
+			if !ok {
+				panic(r)
+			}
+			err = status.Error(codes.Unimplemented, p.Error())
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+// recoverUnimplementedStream is the default grpc.StreamServerInterceptor
+// installed by [NewServer]. It recovers a panic raised by [Unimplemented],
+// converting it into a codes.Unimplemented status error; any other panic is
+// re-raised unchanged.
+func recoverUnimplementedStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			p, ok := r.(unimplementedPanic)
+			if !ok {
+				panic(r)
+			}
+			err = status.Error(codes.Unimplemented, p.Error())
+		}
+	}()
+
+	return handler(srv, ss)
+}
+
+// InvokeUnary reflectively looks up the "<method>Fn" field on stub (a pointer
+// to a struct) and calls it with ctx and req, returning its result. It panics
+// via [Unimplemented] if the field is nil, and panics if stub has no such
+// field or the field is not a func(context.Context, *Req) (*Resp, error).
+//
+// It backs a one-line forwarding method on a stub type, eliminating the
+// nil-check-and-panic boilerplate that would otherwise be repeated for every
+// RPC. See [Stub] for a full example.
+func InvokeUnary[Req, Resp any](stub any, method string, ctx context.Context, req *Req) (*Resp, error) {
+	fn := lookupFn(stub, method)
+	if want := reflect.TypeOf((func(context.Context, *Req) (*Resp, error))(nil)); fn.Type() != want {
+		panic(fmt.Sprintf("grpctest: stub %T field %sFn is %s, want %s", stub, method, fn.Type(), want))
+	}
+
+	out := fn.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(req)})
+
+	resp, _ := out[0].Interface().(*Resp)
+	err, _ := out[1].Interface().(error)
+	return resp, err
+}
+
+// InvokeStream reflectively looks up the "<method>Fn" field on stub (a
+// pointer to a struct) and calls it with args, returning its final result as
+// an error. It panics via [Unimplemented] if the field is nil.
+//
+// It backs a one-line forwarding method on a stub type for a streaming RPC,
+// whose handler signature varies (it may take a request followed by a
+// stream, or just a stream), e.g.:
+//
+//	type EchoServiceStub struct {
+//	    ServerStreamFn func(req *EchoRequest, stream echopb.EchoService_ServerStreamServer) error
+//	}
+//
+//	func (s *EchoServiceStub) ServerStream(req *EchoRequest, stream echopb.EchoService_ServerStreamServer) error {
+//	    return grpctest.InvokeStream(s, "ServerStream", req, stream)
+//	}
+func InvokeStream(stub any, method string, args ...any) error {
+	fn := lookupFn(stub, method)
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		in[i] = reflect.ValueOf(arg)
+	}
+
+	out := fn.Call(in)
+	if len(out) == 0 {
+		return nil
+	}
+
+	err, _ := out[len(out)-1].Interface().(error)
+	return err
+}
+
+// lookupFn returns the reflected "<method>Fn" field on stub, panicking via
+// [Unimplemented] if it is nil.
+func lookupFn(stub any, method string) reflect.Value {
+	v := reflect.ValueOf(stub)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("grpctest: stub must be a pointer to a struct, got %T", stub))
+	}
+
+	field := v.Elem().FieldByName(method + "Fn")
+	if !field.IsValid() || field.Kind() != reflect.Func {
+		panic(fmt.Sprintf("grpctest: stub %T has no %sFn function field", stub, method))
+	}
+	if field.IsNil() {
+		Unimplemented(method)
+	}
+
+	return field
+}
@@ -0,0 +1,124 @@
+package grpctest_test
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/tomasbasham/grpctest"
+	echopb "github.com/tomasbasham/grpctest/testdata/echo/v1"
+)
+
+// echoServiceStub is a hand-rolled stand-in for the kind of stub type a code
+// generator would emit for echopb.EchoServiceServer: one function field per
+// RPC, each forwarded via [grpctest.InvokeUnary], which panics via
+// [grpctest.Unimplemented] when the field is unset.
+type echoServiceStub struct {
+	echopb.UnimplementedEchoServiceServer
+
+	EchoFn func(ctx context.Context, req *echopb.EchoRequest) (*echopb.EchoResponse, error)
+}
+
+func (s *echoServiceStub) Echo(ctx context.Context, req *echopb.EchoRequest) (*echopb.EchoResponse, error) {
+	return grpctest.InvokeUnary[echopb.EchoRequest, echopb.EchoResponse](s, "Echo", ctx, req)
+}
+
+func TestStub(t *testing.T) {
+	t.Parallel()
+
+	s := grpctest.NewServer()
+	s.CloseOnCleanup(t)
+
+	echopb.RegisterEchoServiceServer(s, grpctest.Stub(&echoServiceStub{
+		EchoFn: func(ctx context.Context, req *echopb.EchoRequest) (*echopb.EchoResponse, error) {
+			return &echopb.EchoResponse{Message: "stubbed: " + req.Message}, nil
+		},
+	}))
+	s.Serve()
+
+	conn, err := s.ClientConn()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client := echopb.NewEchoServiceClient(conn)
+	resp, err := client.Echo(context.Background(), &echopb.EchoRequest{Message: "stub test"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "stubbed: stub test"; resp.Message != want {
+		t.Errorf("mismatch:\n  got:  %q\n  want: %q", resp.Message, want)
+	}
+}
+
+func TestStub_ReturnsUnimplementedWhenUnset(t *testing.T) {
+	t.Parallel()
+
+	s := grpctest.NewServer()
+	s.CloseOnCleanup(t)
+
+	echopb.RegisterEchoServiceServer(s, grpctest.Stub(&echoServiceStub{}))
+	s.Serve()
+
+	conn, err := s.ClientConn()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client := echopb.NewEchoServiceClient(conn)
+	_, err = client.Echo(context.Background(), &echopb.EchoRequest{Message: "boom"})
+	if err == nil {
+		t.Fatal("expected RPC to fail when EchoFn is unset, but it succeeded")
+	}
+	if got := status.Code(err); got != codes.Unimplemented {
+		t.Errorf("got status code %v, want %v", got, codes.Unimplemented)
+	}
+}
+
+func TestInvokeUnary(t *testing.T) {
+	t.Parallel()
+
+	t.Run("calls the function field directly", func(t *testing.T) {
+		t.Parallel()
+
+		stub := &echoServiceStub{
+			EchoFn: func(ctx context.Context, req *echopb.EchoRequest) (*echopb.EchoResponse, error) {
+				return &echopb.EchoResponse{Message: req.Message}, nil
+			},
+		}
+
+		resp, err := grpctest.InvokeUnary[echopb.EchoRequest, echopb.EchoResponse](stub, "Echo", context.Background(), &echopb.EchoRequest{Message: "direct"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Message != "direct" {
+			t.Errorf("got %q, want %q", resp.Message, "direct")
+		}
+	})
+
+	t.Run("panics when the field is unset", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected a panic, got none")
+			}
+		}()
+
+		grpctest.InvokeUnary[echopb.EchoRequest, echopb.EchoResponse](&echoServiceStub{}, "Echo", context.Background(), &echopb.EchoRequest{})
+	})
+
+	t.Run("panics when the method has no matching field", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected a panic, got none")
+			}
+		}()
+
+		grpctest.InvokeUnary[echopb.EchoRequest, echopb.EchoResponse](&echoServiceStub{}, "NoSuchMethod", context.Background(), &echopb.EchoRequest{})
+	})
+}
@@ -0,0 +1,105 @@
+package grpctest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Transport controls how a [Server] listens for connections and how
+// [Server.ClientConn] dials it. The default, used by [NewServer], is
+// [NewBufconnTransport], which keeps traffic entirely in-memory and is the
+// fastest option for most tests.
+//
+// bufconn bypasses the real HTTP/2 flow-control and framing paths, so bugs in
+// behaviour like max-concurrent-streams, keepalive, or TLS handshakes can go
+// undetected. Use [NewUnixTransport] or [NewTCPTransport] with
+// [NewServerWithTransport] to opt a test into a real socket without changing
+// its assertions.
+type Transport interface {
+	// listen creates the listener the server will Serve on.
+	listen() (net.Listener, error)
+
+	// dialTarget returns the target [Server.ClientConn] should dial, along
+	// with any dial options required to reach it.
+	dialTarget(l net.Listener) (target string, opts []grpc.DialOption)
+}
+
+// bufconnTransport is the default in-memory [Transport].
+type bufconnTransport struct {
+	size int
+}
+
+// NewBufconnTransport returns a [Transport] backed by an in-memory
+// [bufconn.Listener]. This is the default used by [NewServer].
+func NewBufconnTransport() Transport {
+	return &bufconnTransport{size: getBufferSize()}
+}
+
+func (b *bufconnTransport) listen() (net.Listener, error) {
+	return bufconn.Listen(b.size), nil
+}
+
+func (b *bufconnTransport) dialTarget(l net.Listener) (string, []grpc.DialOption) {
+	bl := l.(*bufconn.Listener)
+	return "passthrough:///test", []grpc.DialOption{
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return bl.Dial()
+		}),
+	}
+}
+
+// unixTransport listens on a unix domain socket in a temporary directory.
+type unixTransport struct {
+	dir string
+}
+
+// NewUnixTransport returns a [Transport] backed by a unix domain socket
+// created in t.TempDir(). The client's authority is set to "localhost", as
+// grpc-go does for unix targets, since a unix socket path has no meaningful
+// host.
+func NewUnixTransport(t testing.TB) Transport {
+	return &unixTransport{dir: t.TempDir()}
+}
+
+func (u *unixTransport) listen() (net.Listener, error) {
+	return net.Listen("unix", filepath.Join(u.dir, "grpctest.sock"))
+}
+
+func (u *unixTransport) dialTarget(l net.Listener) (string, []grpc.DialOption) {
+	return fmt.Sprintf("unix://%s", l.Addr().String()), []grpc.DialOption{
+		grpc.WithAuthority("localhost"),
+	}
+}
+
+// tcpTransport listens on a TCP address, defaulting to an ephemeral loopback
+// port.
+type tcpTransport struct {
+	addr string
+}
+
+// NewTCPTransport returns a [Transport] backed by a TCP listener, exercising
+// the real network stack end to end. With no addr, or an empty one, it
+// listens on an ephemeral loopback port, which is the right choice for tests.
+// A non-empty addr is passed straight to [net.Listen], letting a long-running
+// binary (such as those under examples/) bind a fixed address instead.
+func NewTCPTransport(addr ...string) Transport {
+	a := "127.0.0.1:0"
+	if len(addr) > 0 && addr[0] != "" {
+		a = addr[0]
+	}
+	return &tcpTransport{addr: a}
+}
+
+func (t *tcpTransport) listen() (net.Listener, error) {
+	return net.Listen("tcp", t.addr)
+}
+
+func (tcpTransport) dialTarget(l net.Listener) (string, []grpc.DialOption) {
+	return l.Addr().String(), nil
+}
@@ -0,0 +1,57 @@
+package grpctest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tomasbasham/grpctest"
+	echopb "github.com/tomasbasham/grpctest/testdata/echo/v1"
+)
+
+func TestServer_Transports(t *testing.T) {
+	t.Parallel()
+
+	transports := map[string]func(t *testing.T) grpctest.Transport{
+		"bufconn": func(t *testing.T) grpctest.Transport { return grpctest.NewBufconnTransport() },
+		"unix":    func(t *testing.T) grpctest.Transport { return grpctest.NewUnixTransport(t) },
+		"tcp":     func(t *testing.T) grpctest.Transport { return grpctest.NewTCPTransport() },
+	}
+
+	for name, newTransport := range transports {
+		name, newTransport := name, newTransport
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := grpctest.NewServerWithTransport(newTransport(t))
+			s.CloseOnCleanup(t)
+
+			echopb.RegisterEchoServiceServer(s, &echoServer{})
+			s.Serve()
+
+			conn, err := s.ClientConn()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			mustEcho(t, conn, "transport: "+name)
+
+			if s.Addr() == "" {
+				t.Error("Addr() returned an empty string")
+			}
+			if s.DialTarget() == "" {
+				t.Error("DialTarget() returned an empty string")
+			}
+		})
+	}
+}
+
+func TestUnixTransport_DialTargetUsesUnixScheme(t *testing.T) {
+	t.Parallel()
+
+	s := grpctest.NewServerWithTransport(grpctest.NewUnixTransport(t))
+	s.CloseOnCleanup(t)
+
+	if !strings.HasPrefix(s.DialTarget(), "unix://") {
+		t.Errorf("DialTarget() = %q, want a unix:// target", s.DialTarget())
+	}
+}